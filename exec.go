@@ -0,0 +1,214 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// DefaultExecTimeout is used by the Context-less exec helpers (GoFmtProgramString,
+// IsCommandInstalled, and ConvertPath's cygpath/wslpath fallback)
+// to bound how long an external command is allowed to run before it is killed.
+var DefaultExecTimeout = 10 * time.Second
+
+// gracePeriod is how long RunWithTimeout waits after SIGTERM before escalating to SIGKILL.
+const gracePeriod = 2 * time.Second
+
+/*
+	ExecError is returned by RunWithTimeout when the command fails or times out.
+	It carries the information a caller needs to distinguish "command failed"
+	from "command hung", without having to re-parse err.Error().
+*/
+type ExecError struct {
+	Name     string
+	Args     []string
+	ExitCode int // -1 if the process never started or was killed before exiting normally.
+	Duration time.Duration
+	Stderr   string
+	TimedOut bool
+	Err      error // Underlying error from exec, if any (e.g. context.DeadlineExceeded).
+}
+
+func (e *ExecError) Error() string {
+	if e.TimedOut {
+		return fmt.Sprintf("%s %v: timed out after %v", e.Name, e.Args, e.Duration)
+	}
+	return fmt.Sprintf("%s %v: exit code %d after %v: %s", e.Name, e.Args, e.ExitCode, e.Duration, e.Stderr)
+}
+
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	RunWithTimeout runs name with args, killing it if it has not finished
+	within timeout. stdout and stderr are captured separately (unlike the
+	older helpers in this package, which folded stderr into the returned
+	error's stdout-only buffer).
+
+	On timeout, the process is sent SIGTERM; if it has not exited within a
+	short grace period it is sent SIGKILL. On any failure a *ExecError is
+	returned, so callers can do:
+
+		_, stderr, err := util.RunWithTimeout(5*time.Second, "gofmt")
+		var execErr *util.ExecError
+		if errors.As(err, &execErr) && execErr.TimedOut {
+			...
+		}
+*/
+func RunWithTimeout(timeout time.Duration, name string, args ...string) (stdout string, stderr string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return runContext(ctx, nil, name, args...)
+}
+
+// runContext is the shared implementation behind RunWithTimeout and the *Context helpers below.
+func runContext(ctx context.Context, stdin *bytes.Buffer, name string, args ...string) (stdout string, stderr string, err error) {
+	// exec.Command, not exec.CommandContext: the latter would SIGKILL the process
+	// as soon as ctx is done, pre-empting the SIGTERM-then-SIGKILL sequence below.
+	cmd := exec.Command(name, args...)
+
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	start := time.Now()
+	runErr := cmd.Start()
+	if runErr == nil {
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+
+		select {
+		case runErr = <-done:
+			// Finished (successfully or not) before ctx was done.
+		case <-ctx.Done():
+			// Timed out or cancelled: SIGTERM, then SIGKILL after a grace period.
+			if cmd.Process != nil {
+				cmd.Process.Signal(syscall.SIGTERM)
+			}
+			select {
+			case runErr = <-done:
+			case <-time.After(gracePeriod):
+				if cmd.Process != nil {
+					cmd.Process.Kill()
+				}
+				runErr = <-done
+			}
+		}
+	}
+	duration := time.Since(start)
+
+	stdout = outBuf.String()
+	stderr = errBuf.String()
+
+	if runErr != nil {
+		execErr := &ExecError{
+			Name:     name,
+			Args:     args,
+			ExitCode: -1,
+			Duration: duration,
+			Stderr:   stderr,
+			TimedOut: ctx.Err() == context.DeadlineExceeded,
+			Err:      runErr,
+		}
+		if exitErr, ok := runErr.(*exec.ExitError); ok {
+			execErr.ExitCode = exitErr.ExitCode()
+		}
+		return stdout, stderr, execErr
+	}
+
+	return stdout, stderr, nil
+}
+
+/*
+	FormatSourceContext is the context-aware variant of FormatSource.
+
+	format.Source() does no I/O, so there is nothing to cancel mid-call;
+	ctx is only checked before formatting starts, so that a caller using it
+	to bound a batch of work (FormatSourceContext alongside
+	GoFmtProgramStringContext, say) can bail out early once ctx is done.
+*/
+func FormatSourceContext(ctx context.Context, source string) (formattedSource string, err error) {
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
+	return FormatSource(source)
+}
+
+/*
+	GoFmtProgramStringContext is the context-aware variant of GoFmtProgramString.
+
+	Use it instead of GoFmtProgramString when gofmt might hang or take too
+	long, e.g. on a misconfigured PATH or a huge generated file.
+*/
+func GoFmtProgramStringContext(ctx context.Context, goProgramString string) (formattedGoProgramString string, err error) {
+	formattedGoProgramString = goProgramString
+
+	stdin := bytes.NewBufferString(goProgramString)
+
+	stdout, _, err := runContext(ctx, stdin, "gofmt")
+	if err != nil {
+		return
+	}
+
+	formattedGoProgramString = stdout
+
+	return
+}
+
+/*
+	FilepathAbsContext is the context-aware variant of FilepathAbs.
+
+	Every path style DetectPathStyle() recognises (Cygwin, Msys, WSL,
+	Windows) is translated to Native by ConvertPath's pure-Go translator, so
+	there is currently no subprocess for ctx to bound: this function cannot
+	hang or time out. It takes ctx for API symmetry with the other
+	*Context helpers, and so that a future non-trivial-mount fallback (one
+	ConvertPath can't resolve purely in Go) can honour it without another
+	signature change.
+*/
+func FilepathAbsContext(ctx context.Context, inputPath string) (path string, err error) {
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
+
+	return FilepathAbs(inputPath)
+}
+
+/*
+	IsCommandInstalledContext is the context-aware variant of IsCommandInstalled.
+
+	exec.LookPath() itself does no subprocess I/O, but some PATH lookups (e.g.
+	on a network filesystem) can stall, so this runs the lookup on a goroutine
+	bounded by ctx.
+*/
+func IsCommandInstalledContext(ctx context.Context, commandName string) (bool, error) {
+	type result struct {
+		path string
+		err  error
+	}
+
+	c := make(chan result, 1)
+	go func() {
+		path, err := exec.LookPath(commandName)
+		c <- result{path, err}
+	}()
+
+	select {
+	case r := <-c:
+		if r.err != nil {
+			return false, fmt.Errorf("%v: command %s is not installed in path %s", r.err, commandName, r.path)
+		}
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
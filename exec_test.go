@@ -0,0 +1,67 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunWithTimeoutSuccess(t *testing.T) {
+	stdout, _, err := RunWithTimeout(5*time.Second, "echo", "hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout != "hello\n" {
+		t.Fatalf("expecting %q but got %q", "hello\n", stdout)
+	}
+}
+
+func TestRunWithTimeoutCapturesStderrSeparately(t *testing.T) {
+	_, stderr, err := RunWithTimeout(5*time.Second, "sh", "-c", "echo oops 1>&2; exit 1")
+	if err == nil {
+		t.Fatalf("expecting an error for a non-zero exit")
+	}
+
+	var execErr *ExecError
+	if !errors.As(err, &execErr) {
+		t.Fatalf("expecting a *ExecError, got %T: %v", err, err)
+	}
+	if execErr.ExitCode != 1 {
+		t.Fatalf("expecting exit code 1, got %d", execErr.ExitCode)
+	}
+	if stderr != "oops\n" {
+		t.Fatalf("expecting stderr %q but got %q", "oops\n", stderr)
+	}
+}
+
+func TestRunWithTimeoutKillsHungProcess(t *testing.T) {
+	start := time.Now()
+	_, _, err := RunWithTimeout(200*time.Millisecond, "sleep", "30")
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatalf("expecting a timeout error")
+	}
+
+	var execErr *ExecError
+	if !errors.As(err, &execErr) {
+		t.Fatalf("expecting a *ExecError, got %T: %v", err, err)
+	}
+	if !execErr.TimedOut {
+		t.Fatalf("expecting TimedOut == true")
+	}
+	if elapsed > 5*time.Second {
+		t.Fatalf("expecting the hung process to be killed well before the grace period stacked up, took %v", elapsed)
+	}
+}
+
+func TestFilepathAbsContextRespectsCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := FilepathAbsContext(ctx, "relative/path")
+	if err == nil {
+		t.Fatalf("expecting an error for an already-cancelled context")
+	}
+}
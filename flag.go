@@ -0,0 +1,427 @@
+package util
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+	Validator is a per-flag validation hook run by Set() after the raw string
+	value has been parsed. Typical uses:
+
+		rangeValidator := func(n int) error {
+			if n < 1 || n > 65535 {
+				return fmt.Errorf("must be between 1 and 65535")
+			}
+			return nil
+		}
+
+		var port util.IntFlag
+		port.Validator = rangeValidator
+		flag.Var(&port, "port", "port to listen on")
+*/
+type Validator[T any] func(T) error
+
+/*
+	IntFlag implements the flag.Value interface https://golang.org/pkg/flag/#Value
+
+	It follows the same Exists()/IsSet()/Error()/AllOk()/Print() pattern as
+	StringFlag.
+*/
+type IntFlag struct {
+	val       int
+	exists    bool
+	set       bool
+	err       error
+	Validator Validator[int]
+}
+
+func (f *IntFlag) Set(s string) error {
+	f.exists = true
+
+	if strings.HasPrefix(s, "-") && !isNegativeNumber(s) {
+		f.err = fmt.Errorf("flag needs a valid int argument, not %s", s)
+		return nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		f.err = fmt.Errorf("flag needs a valid int argument, not %s", s)
+		return nil
+	}
+
+	if f.Validator != nil {
+		err = f.Validator(n)
+		if err != nil {
+			f.err = err
+			return nil
+		}
+	}
+
+	f.err = nil
+	f.set = true
+	f.val = n
+
+	return nil
+}
+
+func (f *IntFlag) String() string   { return strconv.Itoa(f.val) }
+func (f *IntFlag) Exists() bool     { return f.exists }
+func (f *IntFlag) IsSet() bool      { return f.set }
+func (f *IntFlag) Error() error     { return f.err }
+func (f *IntFlag) AllOk() bool      { return f.Exists() && f.IsSet() && f.Error() == nil }
+func (f *IntFlag) Val() int         { return f.val }
+
+func (f *IntFlag) Print() {
+	fmt.Fprintf(os.Stderr, "%#v\n", f)
+	fmt.Fprintf(os.Stderr, "&util.IntFlag.String() = %q\n", f.String())
+	fmt.Fprintf(os.Stderr, "&util.IntFlag.Exists() = %t\n", f.Exists())
+	fmt.Fprintf(os.Stderr, "&util.IntFlag.IsSet()  = %t\n", f.IsSet())
+	fmt.Fprintf(os.Stderr, "&util.IntFlag.Error()  = %v\n", f.Error())
+	fmt.Fprintf(os.Stderr, "&util.IntFlag.AllOk()  = %v\n", f.AllOk())
+}
+
+/*
+	Int64Flag implements the flag.Value interface https://golang.org/pkg/flag/#Value
+*/
+type Int64Flag struct {
+	val       int64
+	exists    bool
+	set       bool
+	err       error
+	Validator Validator[int64]
+}
+
+func (f *Int64Flag) Set(s string) error {
+	f.exists = true
+
+	if strings.HasPrefix(s, "-") && !isNegativeNumber(s) {
+		f.err = fmt.Errorf("flag needs a valid int64 argument, not %s", s)
+		return nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		f.err = fmt.Errorf("flag needs a valid int64 argument, not %s", s)
+		return nil
+	}
+
+	if f.Validator != nil {
+		err = f.Validator(n)
+		if err != nil {
+			f.err = err
+			return nil
+		}
+	}
+
+	f.err = nil
+	f.set = true
+	f.val = n
+
+	return nil
+}
+
+func (f *Int64Flag) String() string { return strconv.FormatInt(f.val, 10) }
+func (f *Int64Flag) Exists() bool   { return f.exists }
+func (f *Int64Flag) IsSet() bool    { return f.set }
+func (f *Int64Flag) Error() error   { return f.err }
+func (f *Int64Flag) AllOk() bool    { return f.Exists() && f.IsSet() && f.Error() == nil }
+func (f *Int64Flag) Val() int64     { return f.val }
+
+func (f *Int64Flag) Print() {
+	fmt.Fprintf(os.Stderr, "%#v\n", f)
+	fmt.Fprintf(os.Stderr, "&util.Int64Flag.String() = %q\n", f.String())
+	fmt.Fprintf(os.Stderr, "&util.Int64Flag.Exists() = %t\n", f.Exists())
+	fmt.Fprintf(os.Stderr, "&util.Int64Flag.IsSet()  = %t\n", f.IsSet())
+	fmt.Fprintf(os.Stderr, "&util.Int64Flag.Error()  = %v\n", f.Error())
+	fmt.Fprintf(os.Stderr, "&util.Int64Flag.AllOk()  = %v\n", f.AllOk())
+}
+
+/*
+	Float64Flag implements the flag.Value interface https://golang.org/pkg/flag/#Value
+*/
+type Float64Flag struct {
+	val       float64
+	exists    bool
+	set       bool
+	err       error
+	Validator Validator[float64]
+}
+
+func (f *Float64Flag) Set(s string) error {
+	f.exists = true
+
+	if strings.HasPrefix(s, "-") && !isNegativeNumber(s) {
+		f.err = fmt.Errorf("flag needs a valid float64 argument, not %s", s)
+		return nil
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		f.err = fmt.Errorf("flag needs a valid float64 argument, not %s", s)
+		return nil
+	}
+
+	if f.Validator != nil {
+		err = f.Validator(n)
+		if err != nil {
+			f.err = err
+			return nil
+		}
+	}
+
+	f.err = nil
+	f.set = true
+	f.val = n
+
+	return nil
+}
+
+func (f *Float64Flag) String() string { return strconv.FormatFloat(f.val, 'g', -1, 64) }
+func (f *Float64Flag) Exists() bool   { return f.exists }
+func (f *Float64Flag) IsSet() bool    { return f.set }
+func (f *Float64Flag) Error() error   { return f.err }
+func (f *Float64Flag) AllOk() bool    { return f.Exists() && f.IsSet() && f.Error() == nil }
+func (f *Float64Flag) Val() float64   { return f.val }
+
+func (f *Float64Flag) Print() {
+	fmt.Fprintf(os.Stderr, "%#v\n", f)
+	fmt.Fprintf(os.Stderr, "&util.Float64Flag.String() = %q\n", f.String())
+	fmt.Fprintf(os.Stderr, "&util.Float64Flag.Exists() = %t\n", f.Exists())
+	fmt.Fprintf(os.Stderr, "&util.Float64Flag.IsSet()  = %t\n", f.IsSet())
+	fmt.Fprintf(os.Stderr, "&util.Float64Flag.Error()  = %v\n", f.Error())
+	fmt.Fprintf(os.Stderr, "&util.Float64Flag.AllOk()  = %v\n", f.AllOk())
+}
+
+/*
+	BoolFlag implements the flag.Value interface https://golang.org/pkg/flag/#Value
+*/
+type BoolFlag struct {
+	val       bool
+	exists    bool
+	set       bool
+	err       error
+	Validator Validator[bool]
+}
+
+// IsBoolFlag lets the standard flag package treat -name (with no argument) as -name=true.
+func (f *BoolFlag) IsBoolFlag() bool { return true }
+
+func (f *BoolFlag) Set(s string) error {
+	f.exists = true
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		f.err = fmt.Errorf("flag needs a valid bool argument, not %s", s)
+		return nil
+	}
+
+	if f.Validator != nil {
+		err = f.Validator(b)
+		if err != nil {
+			f.err = err
+			return nil
+		}
+	}
+
+	f.err = nil
+	f.set = true
+	f.val = b
+
+	return nil
+}
+
+func (f *BoolFlag) String() string { return strconv.FormatBool(f.val) }
+func (f *BoolFlag) Exists() bool   { return f.exists }
+func (f *BoolFlag) IsSet() bool    { return f.set }
+func (f *BoolFlag) Error() error   { return f.err }
+func (f *BoolFlag) AllOk() bool    { return f.Exists() && f.IsSet() && f.Error() == nil }
+func (f *BoolFlag) Val() bool      { return f.val }
+
+func (f *BoolFlag) Print() {
+	fmt.Fprintf(os.Stderr, "%#v\n", f)
+	fmt.Fprintf(os.Stderr, "&util.BoolFlag.String() = %q\n", f.String())
+	fmt.Fprintf(os.Stderr, "&util.BoolFlag.Exists() = %t\n", f.Exists())
+	fmt.Fprintf(os.Stderr, "&util.BoolFlag.IsSet()  = %t\n", f.IsSet())
+	fmt.Fprintf(os.Stderr, "&util.BoolFlag.Error()  = %v\n", f.Error())
+	fmt.Fprintf(os.Stderr, "&util.BoolFlag.AllOk()  = %v\n", f.AllOk())
+}
+
+/*
+	DurationFlag implements the flag.Value interface https://golang.org/pkg/flag/#Value
+
+	Accepts anything time.ParseDuration() accepts, e.g. "5s", "2h45m".
+*/
+type DurationFlag struct {
+	val       time.Duration
+	exists    bool
+	set       bool
+	err       error
+	Validator Validator[time.Duration]
+}
+
+func (f *DurationFlag) Set(s string) error {
+	f.exists = true
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		f.err = fmt.Errorf("flag needs a valid duration argument, not %s", s)
+		return nil
+	}
+
+	if f.Validator != nil {
+		err = f.Validator(d)
+		if err != nil {
+			f.err = err
+			return nil
+		}
+	}
+
+	f.err = nil
+	f.set = true
+	f.val = d
+
+	return nil
+}
+
+func (f *DurationFlag) String() string         { return f.val.String() }
+func (f *DurationFlag) Exists() bool           { return f.exists }
+func (f *DurationFlag) IsSet() bool            { return f.set }
+func (f *DurationFlag) Error() error           { return f.err }
+func (f *DurationFlag) AllOk() bool            { return f.Exists() && f.IsSet() && f.Error() == nil }
+func (f *DurationFlag) Val() time.Duration     { return f.val }
+
+func (f *DurationFlag) Print() {
+	fmt.Fprintf(os.Stderr, "%#v\n", f)
+	fmt.Fprintf(os.Stderr, "&util.DurationFlag.String() = %q\n", f.String())
+	fmt.Fprintf(os.Stderr, "&util.DurationFlag.Exists() = %t\n", f.Exists())
+	fmt.Fprintf(os.Stderr, "&util.DurationFlag.IsSet()  = %t\n", f.IsSet())
+	fmt.Fprintf(os.Stderr, "&util.DurationFlag.Error()  = %v\n", f.Error())
+	fmt.Fprintf(os.Stderr, "&util.DurationFlag.AllOk()  = %v\n", f.AllOk())
+}
+
+/*
+	StringSliceFlag implements the flag.Value interface https://golang.org/pkg/flag/#Value
+
+	It accepts either a single comma-separated argument ("-tags=a,b,c") or
+	repeated occurrences of the flag ("-tags=a -tags=b -tags=c"), accumulating
+	values across calls to Set().
+*/
+type StringSliceFlag struct {
+	val       []string
+	exists    bool
+	set       bool
+	err       error
+	Validator Validator[[]string]
+}
+
+func (f *StringSliceFlag) Set(s string) error {
+	f.exists = true
+
+	if strings.HasPrefix(s, "-") {
+		f.err = fmt.Errorf("flag needs a valid string argument, not %s", s)
+		return nil
+	}
+
+	f.val = append(f.val, strings.Split(s, ",")...)
+	f.set = true
+	f.err = nil
+
+	if f.Validator != nil {
+		err := f.Validator(f.val)
+		if err != nil {
+			f.err = err
+		}
+	}
+
+	return nil
+}
+
+func (f *StringSliceFlag) String() string   { return strings.Join(f.val, ",") }
+func (f *StringSliceFlag) Exists() bool     { return f.exists }
+func (f *StringSliceFlag) IsSet() bool      { return f.set }
+func (f *StringSliceFlag) Error() error     { return f.err }
+func (f *StringSliceFlag) AllOk() bool      { return f.Exists() && f.IsSet() && f.Error() == nil }
+func (f *StringSliceFlag) Val() []string    { return f.val }
+
+func (f *StringSliceFlag) Print() {
+	fmt.Fprintf(os.Stderr, "%#v\n", f)
+	fmt.Fprintf(os.Stderr, "&util.StringSliceFlag.String() = %q\n", f.String())
+	fmt.Fprintf(os.Stderr, "&util.StringSliceFlag.Exists() = %t\n", f.Exists())
+	fmt.Fprintf(os.Stderr, "&util.StringSliceFlag.IsSet()  = %t\n", f.IsSet())
+	fmt.Fprintf(os.Stderr, "&util.StringSliceFlag.Error()  = %v\n", f.Error())
+	fmt.Fprintf(os.Stderr, "&util.StringSliceFlag.AllOk()  = %v\n", f.AllOk())
+}
+
+// isNegativeNumber reports whether s looks like "-123" or "-1.5" rather than another flag.
+func isNegativeNumber(s string) bool {
+	if !strings.HasPrefix(s, "-") || len(s) < 2 {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+/*
+	checkable is implemented by StringFlag and all of the typed *Flag types
+	above, allowing Check() and MustCheck() to work against any of them.
+*/
+type checkable interface {
+	AllOk() bool
+	Exists() bool
+	Error() error
+}
+
+/*
+	Check is a polymorphic replacement for CheckStringFlag that works against
+	any of StringFlag, IntFlag, Int64Flag, Float64Flag, BoolFlag,
+	DurationFlag or StringSliceFlag.
+
+		exists, err := util.Check("port", &flags.port, util.FlagRequired)
+		if !exists {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+*/
+func Check(name string, f checkable, required bool) (exists bool, err error) {
+	exists = f.Exists()
+
+	if required && !exists {
+		return false, fmt.Errorf("missing required flag: -%s", name)
+	}
+
+	if exists && f.Error() != nil {
+		return false, fmt.Errorf("flag -%s: %v", name, f.Error())
+	}
+
+	if exists && !f.AllOk() {
+		return false, fmt.Errorf("flag -%s needs a valid argument", name)
+	}
+
+	return exists, nil
+}
+
+/*
+	MustCheck calls Check() and, on failure, prints usage (via flag.Usage)
+	and the error to stderr, then calls os.Exit(1). It replaces the
+	boilerplate:
+
+		_, err := util.Check(name, f, required)
+		if err != nil {
+			flag.Usage()
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+*/
+func MustCheck(name string, f checkable, required bool) {
+	_, err := Check(name, f, required)
+	if err != nil {
+		flag.Usage()
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIntFlagClearsErrorAfterLaterValidSet(t *testing.T) {
+	var f IntFlag
+
+	f.Set("nope")
+	if f.Error() == nil {
+		t.Fatalf("expecting an error after setting an invalid int")
+	}
+
+	f.Set("42")
+	if f.Error() != nil {
+		t.Fatalf("expecting stale error to be cleared after a later valid Set(), got %v", f.Error())
+	}
+	if !f.AllOk() {
+		t.Fatalf("expecting AllOk() to be true after a valid Set()")
+	}
+	if f.Val() != 42 {
+		t.Fatalf("expecting Val() == 42, got %d", f.Val())
+	}
+}
+
+func TestIntFlagValidator(t *testing.T) {
+	var f IntFlag
+	f.Validator = func(n int) error {
+		if n < 0 {
+			return errors.New("must be >= 0")
+		}
+		return nil
+	}
+
+	f.Set("-5")
+	if f.AllOk() {
+		t.Fatalf("expecting AllOk() to be false for a value rejected by the validator")
+	}
+
+	f.Set("5")
+	if !f.AllOk() {
+		t.Fatalf("expecting AllOk() to be true for a value accepted by the validator")
+	}
+}
+
+func TestStringSliceFlagAccumulatesAndClearsError(t *testing.T) {
+	var f StringSliceFlag
+
+	f.Set("-oops")
+	if f.Error() == nil {
+		t.Fatalf("expecting an error after setting a flag-looking token")
+	}
+
+	f.Set("a,b")
+	f.Set("c")
+
+	if f.Error() != nil {
+		t.Fatalf("expecting stale error to be cleared after later valid Set() calls, got %v", f.Error())
+	}
+	if !f.AllOk() {
+		t.Fatalf("expecting AllOk() to be true after valid Set() calls")
+	}
+
+	got := f.Val()
+	expected := []string{"a", "b", "c"}
+	if len(got) != len(expected) {
+		t.Fatalf("expecting %v but got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expecting %v but got %v", expected, got)
+		}
+	}
+}
+
+func TestCheckRequiredFlag(t *testing.T) {
+	var f StringFlag
+
+	exists, err := Check("r", &f, FlagRequired)
+	if exists || err == nil {
+		t.Fatalf("expecting Check() to report a missing required flag")
+	}
+
+	f.Set("value")
+	exists, err = Check("r", &f, FlagRequired)
+	if !exists || err != nil {
+		t.Fatalf("expecting Check() to succeed once the flag is set, got exists=%t err=%v", exists, err)
+	}
+}
@@ -0,0 +1,430 @@
+package util
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+	Logger wraps *log.Logger and adds rotation of the underlying log file.
+
+	Rotation policy (when to rotate, what the next file name is, and what to do
+	before/after rotating) is pluggable via the Archive interface. Three
+	built-in policies are provided: SizeArchive, TimeArchive and CountArchive.
+
+	Typical use:
+
+		archive := util.NewSizeArchive(10 * 1024 * 1024) // 10 MB
+		logger, err := util.NewLogger("/var/log/myprog.log", true, archive)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger.StartArchiver()
+		defer logger.StopArchiver()
+
+		logger.Printf("starting up")
+*/
+type Logger struct {
+	mu      sync.Mutex
+	logger  *log.Logger
+	file    *os.File
+	path    string
+	archive Archive
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+/*
+	Archive defines a log file rotation policy.
+
+	ShouldArchiveNow() is asked, at each Interval() tick, whether the current
+	log file (at path, with info as returned by os.Stat) should be rotated now.
+
+	NextLogFilePath() supplies the path the current file is renamed to when it
+	is archived.
+
+	BeforeArchive() runs (with the file still open) immediately before
+	rotation, and AfterArchive() runs immediately after the old file has been
+	renamed and a fresh file opened at path. Either may be used to, for
+	example, compress the archived file or prune old archives.
+*/
+type Archive interface {
+	ShouldArchiveNow(path string, info os.FileInfo) bool
+	NextLogFilePath(path string, info os.FileInfo) string
+	Interval() time.Duration
+	BeforeArchive(path string, info os.FileInfo) error
+	AfterArchive(oldPath string, newPath string, info os.FileInfo) error
+}
+
+/*
+	NewLogger creates a Logger that writes to path, opening it in append mode
+	if appendMode is true (otherwise the file is truncated). path is passed
+	through FilepathAbs so that Cygwin-style paths are resolved correctly.
+
+	archive may be nil, in which case the log file is never rotated.
+*/
+func NewLogger(path string, appendMode bool, archive Archive) (logger *Logger, err error) {
+	logger = &Logger{
+		archive: archive,
+	}
+
+	err = logger.SetLogFile(path, appendMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+/*
+	SetLogFile (re)opens logger to write to path, in append mode if
+	appendMode is true (otherwise the file is truncated). This follows the
+	pattern used by the external starlog package.
+
+	path is passed through FilepathAbs so that Cygwin-style paths are
+	resolved to the equivalent Windows path before opening.
+*/
+func (logger *Logger) SetLogFile(path string, appendMode bool) error {
+	absPath, err := FilepathAbs(path)
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if appendMode {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(absPath, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("util.Logger.SetLogFile(%q): %v", path, err)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if logger.file != nil {
+		logger.file.Close()
+	}
+
+	logger.file = file
+	logger.path = absPath
+	logger.logger = log.New(file, "", log.LstdFlags)
+
+	return nil
+}
+
+// Printf writes a formatted log line, same as (*log.Logger).Printf().
+func (logger *Logger) Printf(format string, v ...interface{}) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.logger.Printf(format, v...)
+}
+
+// Print writes a log line, same as (*log.Logger).Print().
+func (logger *Logger) Print(v ...interface{}) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.logger.Print(v...)
+}
+
+// Println writes a log line, same as (*log.Logger).Println().
+func (logger *Logger) Println(v ...interface{}) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.logger.Println(v...)
+}
+
+// Close closes the underlying log file.
+func (logger *Logger) Close() error {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if logger.file == nil {
+		return nil
+	}
+	return logger.file.Close()
+}
+
+/*
+	StartArchiver starts a background goroutine which polls at archive.Interval()
+	and rotates the log file whenever archive.ShouldArchiveNow() returns true.
+
+	It is a no-op if logger has no Archive or the archiver is already running.
+*/
+func (logger *Logger) StartArchiver() {
+	if logger.archive == nil {
+		return
+	}
+
+	logger.mu.Lock()
+	if logger.stopCh != nil {
+		logger.mu.Unlock()
+		return
+	}
+	logger.stopCh = make(chan struct{})
+	logger.doneCh = make(chan struct{})
+	logger.mu.Unlock()
+
+	go logger.runArchiver()
+}
+
+// StopArchiver stops the background goroutine started by StartArchiver().
+func (logger *Logger) StopArchiver() {
+	logger.mu.Lock()
+	stopCh := logger.stopCh
+	doneCh := logger.doneCh
+	logger.mu.Unlock()
+
+	if stopCh == nil {
+		return
+	}
+
+	close(stopCh)
+	<-doneCh
+
+	logger.mu.Lock()
+	logger.stopCh = nil
+	logger.doneCh = nil
+	logger.mu.Unlock()
+}
+
+func (logger *Logger) runArchiver() {
+	defer close(logger.doneCh)
+
+	ticker := time.NewTicker(logger.archive.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-logger.stopCh:
+			return
+		case <-ticker.C:
+			err := logger.rotateIfNeeded()
+			if err != nil {
+				where(fmt.Sprintf("util.Logger archiver: %v", err))
+			}
+		}
+	}
+}
+
+func (logger *Logger) rotateIfNeeded() error {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	info, err := os.Stat(logger.path)
+	if err != nil {
+		return err
+	}
+
+	if !logger.archive.ShouldArchiveNow(logger.path, info) {
+		return nil
+	}
+
+	return logger.rotate(info)
+}
+
+// rotate must be called with logger.mu already held.
+func (logger *Logger) rotate(info os.FileInfo) error {
+	err := logger.archive.BeforeArchive(logger.path, info)
+	if err != nil {
+		return fmt.Errorf("BeforeArchive(%q): %v", logger.path, err)
+	}
+
+	newPath := logger.archive.NextLogFilePath(logger.path, info)
+
+	if logger.file != nil {
+		logger.file.Close()
+	}
+
+	err = os.Rename(logger.path, newPath)
+	if err != nil {
+		return fmt.Errorf("rename %q to %q: %v", logger.path, newPath, err)
+	}
+
+	file, err := os.OpenFile(logger.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("reopen %q: %v", logger.path, err)
+	}
+
+	logger.file = file
+	logger.logger = log.New(file, "", log.LstdFlags)
+
+	err = logger.archive.AfterArchive(logger.path, newPath, info)
+	if err != nil {
+		return fmt.Errorf("AfterArchive(%q, %q): %v", logger.path, newPath, err)
+	}
+
+	return nil
+}
+
+/*
+	SizeArchive rotates the log file once it exceeds MaxBytes.
+
+	Archived files are named <path>.<unix-nanosecond-timestamp>.
+*/
+type SizeArchive struct {
+	MaxBytes     int64
+	PollInterval time.Duration
+}
+
+// NewSizeArchive returns a SizeArchive that rotates once the log file exceeds maxBytes.
+func NewSizeArchive(maxBytes int64) *SizeArchive {
+	return &SizeArchive{
+		MaxBytes:     maxBytes,
+		PollInterval: 10 * time.Second,
+	}
+}
+
+func (a *SizeArchive) ShouldArchiveNow(path string, info os.FileInfo) bool {
+	return info.Size() > a.MaxBytes
+}
+
+func (a *SizeArchive) NextLogFilePath(path string, info os.FileInfo) string {
+	// UnixNano(), not Unix(): two rotations within the same wall-clock second would
+	// otherwise produce identical archive names and silently clobber one another.
+	return fmt.Sprintf("%s.%d", path, time.Now().UnixNano())
+}
+
+func (a *SizeArchive) Interval() time.Duration {
+	return a.PollInterval
+}
+
+func (a *SizeArchive) BeforeArchive(path string, info os.FileInfo) error {
+	return nil
+}
+
+func (a *SizeArchive) AfterArchive(oldPath string, newPath string, info os.FileInfo) error {
+	return nil
+}
+
+/*
+	TimeArchive rotates the log file at a fixed calendar boundary: daily or
+	hourly.
+
+	Daily (Period == 24*time.Hour) rotates at local midnight: the boundary is
+	computed from the local calendar day, not from time.Time.Truncate(), since
+	Truncate() measures elapsed time since the absolute zero instant and only
+	lines up with local midnight when the zone offset is a multiple of 24h
+	(i.e. UTC). Any other Period (e.g. hourly) is truncated directly, so it
+	can drift from the wall-clock boundary in timezones whose offset isn't a
+	whole multiple of that period.
+
+	Archived files are named <path>.<YYYYMMDD-hhmmss>, the timestamp being the
+	start of the period in which the file was last modified.
+*/
+type TimeArchive struct {
+	Period time.Duration // e.g. 24*time.Hour for daily, time.Hour for hourly.
+}
+
+// NewDailyArchive returns a TimeArchive that rotates once per day, at local midnight.
+func NewDailyArchive() *TimeArchive {
+	return &TimeArchive{Period: 24 * time.Hour}
+}
+
+// NewHourlyArchive returns a TimeArchive that rotates at the top of every hour.
+func NewHourlyArchive() *TimeArchive {
+	return &TimeArchive{Period: time.Hour}
+}
+
+// boundary returns the start of the period containing t: the local calendar day for
+// daily rotation, or the Truncate() boundary for any other Period.
+func (a *TimeArchive) boundary(t time.Time) time.Time {
+	if a.Period == 24*time.Hour {
+		t = t.Local()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return t.Truncate(a.Period)
+}
+
+func (a *TimeArchive) ShouldArchiveNow(path string, info os.FileInfo) bool {
+	return a.boundary(time.Now()).After(a.boundary(info.ModTime()))
+}
+
+func (a *TimeArchive) NextLogFilePath(path string, info os.FileInfo) string {
+	return fmt.Sprintf("%s.%s", path, a.boundary(info.ModTime()).Format("20060102-150405"))
+}
+
+func (a *TimeArchive) Interval() time.Duration {
+	if a.Period < time.Minute {
+		return a.Period
+	}
+	return time.Minute
+}
+
+func (a *TimeArchive) BeforeArchive(path string, info os.FileInfo) error {
+	return nil
+}
+
+func (a *TimeArchive) AfterArchive(oldPath string, newPath string, info os.FileInfo) error {
+	return nil
+}
+
+/*
+	CountArchive rotates the log file once it exceeds MaxBytes (as per
+	SizeArchive) and, after archiving, deletes all but the most recent
+	KeepCount archived files.
+
+	Archived files are expected to be named <path>.<anything>, matched via
+	path + ".*" in the same directory as path.
+*/
+type CountArchive struct {
+	MaxBytes     int64
+	KeepCount    int
+	PollInterval time.Duration
+}
+
+// NewCountArchive returns a CountArchive that rotates at maxBytes and keeps the most recent keepCount archives.
+func NewCountArchive(maxBytes int64, keepCount int) *CountArchive {
+	return &CountArchive{
+		MaxBytes:     maxBytes,
+		KeepCount:    keepCount,
+		PollInterval: 10 * time.Second,
+	}
+}
+
+func (a *CountArchive) ShouldArchiveNow(path string, info os.FileInfo) bool {
+	return info.Size() > a.MaxBytes
+}
+
+func (a *CountArchive) NextLogFilePath(path string, info os.FileInfo) string {
+	return fmt.Sprintf("%s.%d", path, time.Now().UnixNano())
+}
+
+func (a *CountArchive) Interval() time.Duration {
+	return a.PollInterval
+}
+
+func (a *CountArchive) BeforeArchive(path string, info os.FileInfo) error {
+	return nil
+}
+
+func (a *CountArchive) AfterArchive(oldPath string, newPath string, info os.FileInfo) error {
+	matches, err := filepath.Glob(oldPath + ".*")
+	if err != nil {
+		return err
+	}
+
+	if len(matches) <= a.KeepCount {
+		return nil
+	}
+
+	// Oldest first (archive names embed an increasing timestamp).
+	sort.Strings(matches)
+
+	for _, oldArchive := range matches[:len(matches)-a.KeepCount] {
+		err = os.Remove(oldArchive)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
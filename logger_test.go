@@ -0,0 +1,129 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSizeArchiveShouldArchiveNow(t *testing.T) {
+	f, err := os.CreateTemp("", "util_logger_test_*.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString("0123456789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	info, err := os.Stat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	small := NewSizeArchive(5)
+	if !small.ShouldArchiveNow(f.Name(), info) {
+		t.Fatalf("expecting ShouldArchiveNow to be true for a 10-byte file against a 5-byte limit")
+	}
+
+	large := NewSizeArchive(1000)
+	if large.ShouldArchiveNow(f.Name(), info) {
+		t.Fatalf("expecting ShouldArchiveNow to be false for a 10-byte file against a 1000-byte limit")
+	}
+}
+
+func TestTimeArchiveDailyBoundaryIsLocalMidnight(t *testing.T) {
+	daily := NewDailyArchive()
+
+	t1 := time.Date(2026, time.July, 26, 23, 0, 0, 0, time.Local)
+	t2 := time.Date(2026, time.July, 27, 1, 0, 0, 0, time.Local)
+
+	b1 := daily.boundary(t1)
+	b2 := daily.boundary(t2)
+
+	if b1.Equal(b2) {
+		t.Fatalf("expecting different local-midnight boundaries either side of midnight, got the same: %v", b1)
+	}
+
+	expected := time.Date(2026, time.July, 26, 0, 0, 0, 0, time.Local)
+	if !b1.Equal(expected) {
+		t.Fatalf("expecting boundary %v but got %v", expected, b1)
+	}
+}
+
+func TestCountArchiveKeepsOnlyKeepCount(t *testing.T) {
+	dir, err := os.MkdirTemp("", "util_logger_test_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := dir + "/app.log"
+
+	var archives []string
+	for i := 0; i < 5; i++ {
+		archivePath := logPath + "." + time.Unix(int64(i), 0).Format("20060102-150405")
+		err = os.WriteFile(archivePath, []byte("x"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archives = append(archives, archivePath)
+	}
+
+	countArchive := NewCountArchive(100, 2)
+	err = countArchive.AfterArchive(logPath, archives[len(archives)-1], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remaining, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(remaining) != 2 {
+		t.Fatalf("expecting 2 archives to remain but found %d", len(remaining))
+	}
+}
+
+func TestLoggerStartArchiverRotatesRealFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "util_logger_test_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "app.log")
+
+	archive := NewSizeArchive(10)
+	archive.PollInterval = 20 * time.Millisecond
+
+	logger, err := NewLogger(logPath, true, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.StartArchiver()
+	defer logger.StopArchiver()
+
+	logger.Printf("this line alone is comfortably over ten bytes")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) >= 2 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("expecting StartArchiver to have rotated %s into a second file within the deadline", logPath)
+}
@@ -0,0 +1,184 @@
+package util
+
+import (
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+/*
+	PathStyle identifies the flavour of path a string is written in, so that
+	ConvertPath() knows how to translate it.
+*/
+type PathStyle int
+
+const (
+	Native  PathStyle = iota // Whatever filepath.Abs() would produce on this OS.
+	Cygwin                   // /cygdrive/c/foo
+	Msys                     // /c/foo (MSYS2, MinGW, Git-Bash)
+	WSL                      // /mnt/c/foo, or \\wsl$\<distro>\foo from the Windows side
+	Windows                  // C:\foo
+)
+
+var (
+	cygdrivePattern = regexp.MustCompile(`^/cygdrive/([a-zA-Z])(/.*)?$`)
+	msysPattern     = regexp.MustCompile(`^/([a-zA-Z])(/.*)?$`)
+	wslPattern      = regexp.MustCompile(`^/mnt/([a-zA-Z])(/.*)?$`)
+	windowsPattern  = regexp.MustCompile(`^([a-zA-Z]):[\\/](.*)$`)
+	wslUNCPattern   = regexp.MustCompile(`(?i)^\\\\wsl\$\\[^\\]+(\\.*)?$`)
+)
+
+/*
+	DetectPathStyle inspects s and reports which PathStyle it appears to be
+	written in. It returns Native if none of the recognised Unix-on-Windows
+	prefixes (/cygdrive/<drive>, /mnt/<drive>, /<drive>), the WSL UNC prefix
+	(\\wsl$\<distro>), or the Windows "<drive>:\" prefix match.
+*/
+func DetectPathStyle(s string) PathStyle {
+	switch {
+	case cygdrivePattern.MatchString(s):
+		return Cygwin
+	case wslPattern.MatchString(s):
+		return WSL
+	case wslUNCPattern.MatchString(s):
+		return WSL
+	case msysPattern.MatchString(s):
+		return Msys
+	case windowsPattern.MatchString(s):
+		return Windows
+	default:
+		return Native
+	}
+}
+
+/*
+	ConvertPath converts input from whatever PathStyle it is currently
+	written in to the PathStyle named by to.
+
+	The common cases (/cygdrive/<drive>/..., /mnt/<drive>/..., /<drive>/...,
+	\\wsl$\<distro>\... and <drive>:\...) are translated with a pure-Go
+	translator, with no external process involved. Anything not matching one
+	of those shapes is returned unchanged when to is Native or Msys; when to
+	is Cygwin or Windows it is passed to cygpath, and when to is WSL to
+	wslpath. If that tool fails (or is not installed), input is returned
+	unchanged together with the error.
+
+	Converting to Native never fabricates a drive-letter path on a non-Windows
+	host: on such a host a /cygdrive/, /mnt/ or /<drive> path is already a
+	usable native path (under Cygwin/WSL/MSYS2 respectively), so it is
+	returned unchanged rather than rewritten into a Windows-style path that
+	wouldn't resolve there.
+*/
+func ConvertPath(input string, to PathStyle) (output string, err error) {
+	if wslUNCPattern.MatchString(input) {
+		return convertWSLUNCPath(input, to)
+	}
+
+	from := DetectPathStyle(input)
+
+	var drive, rest string
+	var haveDriveRest bool
+
+	switch from {
+	case Cygwin:
+		m := cygdrivePattern.FindStringSubmatch(input)
+		drive, rest, haveDriveRest = m[1], m[2], true
+	case WSL:
+		m := wslPattern.FindStringSubmatch(input)
+		drive, rest, haveDriveRest = m[1], m[2], true
+	case Msys:
+		m := msysPattern.FindStringSubmatch(input)
+		drive, rest, haveDriveRest = m[1], m[2], true
+	case Windows:
+		m := windowsPattern.FindStringSubmatch(input)
+		drive, rest, haveDriveRest = m[1], "/"+strings.Replace(m[2], `\`, "/", -1), true
+	}
+
+	if !haveDriveRest {
+		// Native, or not a recognised drive-letter path: nothing to translate.
+		if to == from || to == Native {
+			return input, nil
+		}
+		return convertPathExternal(input, to)
+	}
+
+	drive = strings.ToLower(drive)
+
+	switch to {
+	case Cygwin:
+		return "/cygdrive/" + drive + rest, nil
+	case Msys:
+		return "/" + drive + rest, nil
+	case WSL:
+		return "/mnt/" + drive + rest, nil
+	case Windows:
+		return windowsPath(drive, rest), nil
+	case Native:
+		if runtime.GOOS == "windows" {
+			return windowsPath(drive, rest), nil
+		}
+		// A drive-letter path is already native on a non-Windows host (e.g. under
+		// WSL, Cygwin or MSYS2); don't rewrite it into a Windows-only form.
+		return input, nil
+	default:
+		return convertPathExternal(input, to)
+	}
+}
+
+// windowsPath renders drive (a single letter) and rest (forward-slash-separated) as "C:\..." .
+func windowsPath(drive, rest string) string {
+	return strings.ToUpper(drive) + ":\\" + strings.TrimPrefix(strings.Replace(rest, "/", `\`, -1), `\`)
+}
+
+// convertWSLUNCPath converts a \\wsl$\<distro>\... path (the Windows-side view of a WSL
+// distro's filesystem) to the PathStyle named by to.
+func convertWSLUNCPath(input string, to PathStyle) (output string, err error) {
+	m := wslUNCPattern.FindStringSubmatch(input)
+	rest := m[1] // e.g. `\home\user`, or "" for the distro root.
+
+	switch to {
+	case WSL, Native:
+		if runtime.GOOS == "windows" {
+			// The UNC form is already native on Windows.
+			return input, nil
+		}
+		if rest == "" {
+			return "/", nil
+		}
+		return strings.Replace(rest, `\`, "/", -1), nil
+	case Windows:
+		return input, nil
+	default:
+		// Cygwin/Msys have no concept of a WSL distro root; there's no drive letter
+		// to translate purely in Go, so defer to convertPathExternal (cygpath for
+		// Cygwin; Msys gets input back unchanged).
+		return convertPathExternal(input, to)
+	}
+}
+
+// convertPathExternal falls back to the cygpath/wslpath binaries for paths the pure-Go translator doesn't cover.
+// It is bounded by DefaultExecTimeout via RunWithTimeout, so a wedged cygpath/wslpath can't hang the caller.
+func convertPathExternal(input string, to PathStyle) (output string, err error) {
+	var name string
+	var args []string
+
+	switch to {
+	case Cygwin:
+		name, args = "cygpath", []string{"-u", input}
+	case Windows, Native:
+		name, args = "cygpath", []string{"-w", input}
+	case WSL:
+		name, args = "wslpath", []string{"-u", input}
+	default:
+		return input, nil
+	}
+
+	stdout, _, err := RunWithTimeout(DefaultExecTimeout, name, args...)
+	if err != nil {
+		return input, err
+	}
+
+	output = strings.Replace(stdout, "\n", "", -1)
+
+	return output, nil
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestDetectPathStyle(t *testing.T) {
+	cases := map[string]PathStyle{
+		"/cygdrive/c/foo":          Cygwin,
+		"/mnt/c/foo":               WSL,
+		"/c/foo":                   Msys,
+		`C:\foo\bar`:               Windows,
+		`C:/foo/bar`:               Windows,
+		`\\wsl$\Ubuntu\home\user`: WSL,
+		"/home/user":               Native,
+	}
+
+	for input, expected := range cases {
+		got := DetectPathStyle(input)
+		if got != expected {
+			t.Errorf("DetectPathStyle(%q) = %v, expecting %v", input, got, expected)
+		}
+	}
+}
+
+func TestConvertPathDriveLetterRoundTrip(t *testing.T) {
+	cases := []struct {
+		input string
+		to    PathStyle
+		want  string
+	}{
+		{"/mnt/c/foo/bar", Cygwin, "/cygdrive/c/foo/bar"},
+		{"/mnt/c/foo/bar", Msys, "/c/foo/bar"},
+		{"/cygdrive/c/foo/bar", WSL, "/mnt/c/foo/bar"},
+		{"/c/foo/bar", WSL, "/mnt/c/foo/bar"},
+		{`C:\foo\bar`, WSL, "/mnt/c/foo/bar"},
+	}
+
+	for _, c := range cases {
+		got, err := ConvertPath(c.input, c.to)
+		if err != nil {
+			t.Errorf("ConvertPath(%q, %v): unexpected error: %v", c.input, c.to, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("ConvertPath(%q, %v) = %q, expecting %q", c.input, c.to, got, c.want)
+		}
+	}
+}
+
+// TestConvertPathToNativeDoesNotFabricateWindowsPath guards against ConvertPath
+// rendering a backslashed Windows path as the Native target on a non-Windows host,
+// where it wouldn't resolve to anything on the real filesystem.
+func TestConvertPathToNativeDoesNotFabricateWindowsPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this case only applies on a non-Windows host")
+	}
+
+	input := "/mnt/c/Users/x/app.log"
+	got, err := ConvertPath(input, Native)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != input {
+		t.Fatalf("ConvertPath(%q, Native) = %q, expecting it unchanged on %s", input, got, runtime.GOOS)
+	}
+}
+
+func TestConvertWSLUNCPathToNative(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("this case only applies on a non-Windows (WSL-side) host")
+	}
+
+	got, err := ConvertPath(`\\wsl$\Ubuntu\home\user`, Native)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "/home/user" {
+		t.Fatalf(`ConvertPath(\\wsl$\Ubuntu\home\user, Native) = %q, expecting "/home/user"`, got)
+	}
+}
@@ -2,14 +2,13 @@ package util
 
 import (
 	"bufio"
-	"bytes"
+	"context"
 	"fmt"
 	"go/format"
 	"io"
 	"log"
 	"math"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -182,7 +181,7 @@ func CheckStringFlag(name string, arg string, required bool) (exists bool, err e
 }
 
 /*
-	Handle Cygwin environment.
+	Handle Cygwin, MSYS2/MinGW, Git-Bash and WSL environments.
 
 	The problem:
 		cygwinPath := "/cygdrive/c/mypath/myfile"
@@ -191,33 +190,19 @@ func CheckStringFlag(name string, arg string, required bool) (exists bool, err e
 	returns: "C:/cygdrive/c/mypath/myfile"
 
 	It should return: "C:/mypath/myfile"
+
+	FilepathAbs is a thin wrapper around ConvertPath(inputPath, Native): any
+	recognised /cygdrive/<drive>/..., /mnt/<drive>/... (WSL), /<drive>/...
+	(MSYS2/MinGW/Git-Bash), \\wsl$\<distro>\... or <drive>:\... prefix is
+	translated to a native path; everything else is passed to filepath.Abs().
 */
 func FilepathAbs(inputPath string) (path string, err error) {
-	var OSTYPE string
-	const cygwinRootOfAllDrives = "/cygdrive/"
-	if strings.HasPrefix(inputPath, cygwinRootOfAllDrives) {
-		OSTYPE = "cygwin"
-		// OSTYPE := os.Getenv("OSTYPE")	// Is not helpful (returns nothing on Windows 10)
-	}
-	if OSTYPE == "cygwin" { // Atypical case: cygwin drive.
-		// Use cygwin utility cygpath to convert cygwin path to windows path.
-		const executable = "cygpath"
-		const flag = "-w"
-		var cmd *exec.Cmd = exec.Command(executable, flag, inputPath)
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		err = cmd.Run()
-		if err != nil {
-			err = fmt.Errorf("%s exit code %v error: %s", executable, err, out.String())
-			return
-		}
-		path = out.String()
-		// cygpath or cygwin bash appends path with an unwelcome new line.
-		path = strings.Replace(path, "\n", "", -1)
-	} else { // Typical case.
-		path, err = filepath.Abs(inputPath)
+	if DetectPathStyle(inputPath) != Native {
+		return ConvertPath(inputPath, Native)
 	}
 
+	path, err = filepath.Abs(inputPath)
+
 	return
 }
 
@@ -248,27 +233,15 @@ func FilepathAbs(inputPath string) (path string, err error) {
 	Because this function calls out to gofmt in the operating system, the potential
 	for failure is possible on some machines (and hence not testable during development).
 	Hence a more forgiving return of its input string so as to avoid crunching user data.
+
+	GoFmtProgramString is bounded by DefaultExecTimeout; use
+	GoFmtProgramStringContext to supply your own context instead.
 */
 func GoFmtProgramString(goProgramString string) (formattedGoProgramString string, err error) {
-	// We return the input string even if error, so as to not crunch it in the calling function.
-	formattedGoProgramString = goProgramString
-
-	var cmd *exec.Cmd = exec.Command("gofmt")
-
-	var fileBytes []byte = []byte(goProgramString)
-	cmd.Stdin = bytes.NewBuffer(fileBytes)
-
-	var out bytes.Buffer
-	cmd.Stdout = &out
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultExecTimeout)
+	defer cancel()
 
-	err = cmd.Run()
-	if err != nil {
-		return
-	}
-
-	formattedGoProgramString = out.String()
-
-	return
+	return GoFmtProgramStringContext(ctx, goProgramString)
 }
 
 func FormatSource(source string) (formattedSource string, err error) {
@@ -523,14 +496,15 @@ func GulpFromPipeWithTimeout(timeout time.Duration) (input string, err error) {
 
 /*
 	Check whether commandName is installed on this machine.
+
+	IsCommandInstalled is bounded by DefaultExecTimeout; use
+	IsCommandInstalledContext to supply your own context instead.
 */
 func IsCommandInstalled(commandName string) (bool, error) {
-	path, err := exec.LookPath(commandName)
-	if err != nil {
-		return false, fmt.Errorf("%v: command %s is not installed in path %s", err, commandName, path)
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultExecTimeout)
+	defer cancel()
 
-	return true, nil
+	return IsCommandInstalledContext(ctx, commandName)
 }
 
 func ProgName() string {